@@ -12,12 +12,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package fletcher4 // import go.solidsystem.no/fletcher4
+package fletcher4 // import "go.solidsystem.no/fletcher4"
 
 import (
+	"encoding"
 	"encoding/binary"
 	"fmt"
 	"hash"
+
+	"go.solidsystem.no/fletcher4/internal/asm"
 )
 
 // Extension of common Hash interface to easily get 4 computed checksum words
@@ -34,9 +37,27 @@ const BlockSize = 4
 
 // digest represents the partial evaluation of a fletcher4 checksum.
 type digest struct {
-	sum  [4]uint64
-	buf  [BlockSize]byte
-	nbuf int
+	sum    [4]uint64
+	buf    [BlockSize]byte
+	nbuf   int
+	kernel asm.Kernel
+	order  binary.ByteOrder
+}
+
+// Option configures a digest constructed by New, NewScalar or NewFromBinary.
+type Option func(*digest)
+
+// WithByteOrder selects the byte order used to decode each 32-bit input
+// word. OpenZFS stores fletcher4 checksums in the native byte order of the
+// pool that wrote them, so importing a pool across architectures requires
+// decoding its blocks with the matching order. The default, used when no
+// Option is given, is binary.LittleEndian.
+//
+// Only LittleEndian gets the SIMD-accelerated kernels from internal/asm,
+// which are written against that layout; any other order falls back to a
+// portable word-at-a-time decode.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(d *digest) { d.order = order }
 }
 
 func (d *digest) Reset() {
@@ -45,13 +66,58 @@ func (d *digest) Reset() {
 	d.nbuf = 0
 }
 
-// New returns a new Fletcher64x4 (hash.Hash) computing the fletcher4 checksum.
-func New() Fletcher64x4 {
+// newDigest builds a digest with the given default kernel, applies opts on
+// top, and is the shared constructor body for New, NewScalar and
+// NewFromBinary.
+func newDigest(kernel asm.Kernel, opts ...Option) *digest {
 	d := new(digest)
+	d.kernel = kernel
+	d.order = binary.LittleEndian
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// New returns a new Fletcher64x4 (hash.Hash) computing the fletcher4 checksum.
+// It dispatches to the fastest SIMD kernel available on the current CPU,
+// chosen once at init() time; see NewScalar for a portable alternative.
+// By default input words are decoded little-endian; pass WithByteOrder to
+// change that.
+func New(opts ...Option) Fletcher64x4 {
+	d := newDigest(asm.Best, opts...)
+	d.Reset()
+	return d
+}
+
+// NewWithByteOrder is a shorthand for New(WithByteOrder(order)).
+func NewWithByteOrder(order binary.ByteOrder) Fletcher64x4 {
+	return New(WithByteOrder(order))
+}
+
+// NewScalar returns a Fletcher64x4 that always uses the plain, portable
+// Go implementation, bypassing CPU dispatch. It exists for platforms asm
+// kernels don't cover and for differential fuzz testing against them.
+func NewScalar(opts ...Option) Fletcher64x4 {
+	d := newDigest(asm.Scalar, opts...)
 	d.Reset()
 	return d
 }
 
+// NewFromBinary reconstructs a Fletcher64x4 from a blob previously produced
+// by MarshalBinary, picking up streaming exactly where it left off. The
+// reconstructed digest dispatches to the fastest kernel on this machine,
+// same as New; kernel choice is a runtime detail, not part of the
+// checksum state. The marshaled blob doesn't carry byte order either, so
+// pass WithByteOrder again here if the original digest used one.
+func NewFromBinary(data []byte, opts ...Option) (Fletcher64x4, error) {
+	d := newDigest(asm.Best, opts...)
+	if err := d.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
 func (d *digest) Size() int { return Size }
 
 func (d *digest) BlockSize() int {
@@ -62,12 +128,16 @@ func (d *digest) update(p []byte) {
 	if len(p)%BlockSize != 0 {
 		panic(fmt.Sprintf("update to Fletcher64x4 checksummer digest must be a multiple of %v bytes.", BlockSize))
 	}
-	for i := 0; i < len(p); i += BlockSize {
-		d.sum[0] += uint64(binary.LittleEndian.Uint32(p[i : i+BlockSize]))
-		d.sum[1] += d.sum[0]
-		d.sum[2] += d.sum[1]
-		d.sum[3] += d.sum[2]
+	if len(p) == 0 {
+		return
 	}
+	var part [4]uint64
+	if d.order == binary.LittleEndian {
+		part = d.kernel.Sum(p)
+	} else {
+		part = asm.SumWithOrder(p, d.order)
+	}
+	d.sum = asm.Combine(d.sum, part, uint64(len(p)/BlockSize))
 }
 
 func (d *digest) Write(p []byte) (n int, err error) {
@@ -114,7 +184,7 @@ func (d *digest) Sum64x4() [4]uint64 {
 		var buf [BlockSize]byte
 		copy(buf[:], d.buf[:d.nbuf])
 		// Pad with zeros (implicit since buf is zero-initialized)
-		val := uint64(binary.LittleEndian.Uint32(buf[:]))
+		val := uint64(d.order.Uint32(buf[:]))
 		sum[0] += val
 		sum[1] += sum[0]
 		sum[2] += sum[1]
@@ -122,3 +192,55 @@ func (d *digest) Sum64x4() [4]uint64 {
 	}
 	return sum
 }
+
+// marshaledMagic identifies a fletcher4 digest blob, following the same
+// "short magic + version byte" convention crc32/crc64/fnv use for their
+// hash.Hash checkpoints.
+const marshaledMagic = "fl4\x01"
+
+// marshaledSize is the total length of a MarshalBinary blob: the magic,
+// the four partial sums, the nbuf count, and the BlockSize-byte buf tail.
+const marshaledSize = len(marshaledMagic) + 4*8 + 1 + BlockSize
+
+var (
+	_ encoding.BinaryMarshaler   = (*digest)(nil)
+	_ encoding.BinaryUnmarshaler = (*digest)(nil)
+)
+
+// MarshalBinary snapshots the digest's state so a long-running streaming
+// consumer can checkpoint and later resume with UnmarshalBinary.
+func (d *digest) MarshalBinary() ([]byte, error) {
+	b := make([]byte, marshaledSize)
+	off := copy(b, marshaledMagic)
+	for i, s := range d.sum {
+		binary.LittleEndian.PutUint64(b[off+i*8:], s)
+	}
+	off += 4 * 8
+	b[off] = byte(d.nbuf)
+	off++
+	copy(b[off:], d.buf[:])
+	return b, nil
+}
+
+// UnmarshalBinary restores a digest from a blob produced by MarshalBinary.
+func (d *digest) UnmarshalBinary(data []byte) error {
+	if len(data) != marshaledSize {
+		return fmt.Errorf("fletcher4: invalid blob length %v, want %v", len(data), marshaledSize)
+	}
+	if string(data[:len(marshaledMagic)]) != marshaledMagic {
+		return fmt.Errorf("fletcher4: invalid magic %q", data[:len(marshaledMagic)])
+	}
+	off := len(marshaledMagic)
+	for i := range d.sum {
+		d.sum[i] = binary.LittleEndian.Uint64(data[off+i*8:])
+	}
+	off += 4 * 8
+	nbuf := int(data[off])
+	off++
+	if nbuf >= BlockSize {
+		return fmt.Errorf("fletcher4: invalid nbuf %v, want 0..%v", nbuf, BlockSize-1)
+	}
+	d.nbuf = nbuf
+	copy(d.buf[:], data[off:])
+	return nil
+}
@@ -16,7 +16,9 @@ package fletcher4
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math/rand"
 	"testing"
 )
 
@@ -157,3 +159,157 @@ func TestUnalignedWrite2(t *testing.T) {
 		t.Errorf("Checksum Sum method call 2 returned wrong result.\nExpected %x,\ngot: %x)", sum, expSum2)
 	}
 }
+
+// Fuzz-differential test: New (dispatched to the fastest SIMD kernel found
+// on this CPU) must agree with NewScalar (the portable reference) for any
+// buffer length and any split into Write calls, including unaligned ones.
+func TestSIMDMatchesScalar(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for trial := 0; trial < 200; trial++ {
+		n := r.Intn(8192)
+		p := make([]byte, n)
+		r.Read(p)
+
+		want := NewScalar()
+		got := New()
+
+		for off := 0; off < n; {
+			chunk := r.Intn(37) + 1
+			if off+chunk > n {
+				chunk = n - off
+			}
+			want.Write(p[off : off+chunk])
+			got.Write(p[off : off+chunk])
+			off += chunk
+		}
+
+		wantSum := want.Sum64x4()
+		gotSum := got.Sum64x4()
+		if wantSum != gotSum {
+			t.Fatalf("trial %d, len %d: scalar %v, dispatched %v", trial, n, wantSum, gotSum)
+		}
+	}
+}
+
+// Marshal/unmarshal round trip: streaming the same data in one piece must
+// equal streaming it in two pieces with a checkpoint/resume in between,
+// including at unaligned offsets where nbuf != 0.
+func TestMarshalUnmarshalBinary(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for trial := 0; trial < 100; trial++ {
+		n := r.Intn(4096)
+		p := make([]byte, n)
+		r.Read(p)
+		split := 0
+		if n > 0 {
+			split = r.Intn(n + 1)
+		}
+
+		want := New()
+		want.Write(p)
+		wantSum := want.Sum64x4()
+
+		first := New()
+		first.Write(p[:split])
+
+		blob, err := first.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+		if err != nil {
+			t.Fatalf("trial %d: MarshalBinary: %v", trial, err)
+		}
+
+		resumed, err := NewFromBinary(blob)
+		if err != nil {
+			t.Fatalf("trial %d: NewFromBinary: %v", trial, err)
+		}
+		resumed.Write(p[split:])
+
+		gotSum := resumed.Sum64x4()
+		if gotSum != wantSum {
+			t.Fatalf("trial %d: len %d, split %d: got %v, want %v", trial, n, split, gotSum, wantSum)
+		}
+	}
+}
+
+func TestUnmarshalBinaryRejectsBadInput(t *testing.T) {
+	if _, err := NewFromBinary(nil); err == nil {
+		t.Error("expected error for empty blob")
+	}
+	blob, err := New().(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := append([]byte{}, blob...)
+	bad[0] ^= 0xff
+	if _, err := NewFromBinary(bad); err == nil {
+		t.Error("expected error for bad magic")
+	}
+	if _, err := NewFromBinary(blob[:len(blob)-1]); err == nil {
+		t.Error("expected error for truncated blob")
+	}
+
+	// nbuf lives right after the magic and the four partial sums; a digest
+	// never buffers a full word (Write flushes at BlockSize), so nbuf ==
+	// BlockSize is not a state UnmarshalBinary should accept.
+	nbufOff := len(marshaledMagic) + 4*8
+	fullWord := append([]byte{}, blob...)
+	fullWord[nbufOff] = BlockSize
+	if _, err := NewFromBinary(fullWord); err == nil {
+		t.Error("expected error for nbuf == BlockSize")
+	}
+}
+
+// Single 4-byte word interpreted big-endian: bytes {1,2,3,4} as a
+// big-endian uint32 is 0x01020304, and since there's only one word every
+// partial sum equals that value (mirrors TestChecksummer1's little-endian
+// case).
+func TestBigEndianByteOrder(t *testing.T) {
+	inp := []byte{1, 2, 3, 4}
+	exp := hexRes{"1020304", "1020304", "1020304", "1020304"}
+
+	h := NewWithByteOrder(binary.BigEndian)
+	if _, err := h.Write(inp); err != nil {
+		t.Fatal(err)
+	}
+	compare(t, "BigEndian single word", exp, h.Sum64x4())
+}
+
+// Reversing the bytes within each 4-byte word and swapping the decode
+// order should be a no-op: a big-endian reader of the byte-reversed words
+// sees the same 32-bit values as a little-endian reader of the original
+// bytes, so both digests must agree.
+func TestByteOrderWordSwapEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	for trial := 0; trial < 50; trial++ {
+		words := r.Intn(256)
+		p := make([]byte, words*BlockSize)
+		r.Read(p)
+
+		swapped := make([]byte, len(p))
+		for i := 0; i < len(p); i += BlockSize {
+			for j := 0; j < BlockSize; j++ {
+				swapped[i+j] = p[i+BlockSize-1-j]
+			}
+		}
+
+		le := New()
+		le.Write(p)
+
+		be := NewWithByteOrder(binary.BigEndian)
+		be.Write(swapped)
+
+		if le.Sum64x4() != be.Sum64x4() {
+			t.Fatalf("trial %d: little-endian %v != big-endian(word-swapped) %v", trial, le.Sum64x4(), be.Sum64x4())
+		}
+	}
+}
+
+// An unaligned tail must also be decoded with the configured byte order.
+func TestByteOrderUnalignedTail(t *testing.T) {
+	h := NewWithByteOrder(binary.BigEndian)
+	// "hel" padded to 4 bytes big-endian: 0x68656c00
+	if _, err := h.Write([]byte("hel")); err != nil {
+		t.Fatal(err)
+	}
+	exp := hexRes{"68656c00", "68656c00", "68656c00", "68656c00"}
+	compare(t, "BigEndian unaligned tail", exp, h.Sum64x4())
+}
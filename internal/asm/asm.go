@@ -0,0 +1,119 @@
+package asm
+
+import "encoding/binary"
+
+// Kernel is one fletcher4 implementation: a function computing the quad
+// checksum of an aligned buffer as if it were the start of a fresh stream.
+type Kernel struct {
+	Name string
+	Sum  func(p []byte) [4]uint64
+}
+
+// ScalarSum computes the fletcher4 quad sum of p (len(p) must be a multiple
+// of 4) using the plain, portable word-at-a-time recurrence. It is the
+// reference every SIMD kernel is checked against, and backs both NewScalar
+// and the scalar tail every SIMD kernel falls back to for its remainder.
+func ScalarSum(p []byte) [4]uint64 {
+	return SumWithOrder(p, binary.LittleEndian)
+}
+
+// SumWithOrder computes the fletcher4 quad sum of p (len(p) must be a
+// multiple of 4) decoding each 32-bit word with order instead of the
+// little-endian layout ScalarSum and every SIMD kernel assume. It has no
+// SIMD acceleration; callers that need a non-default order trade the fast
+// path for correct decoding of that order's on-disk words.
+func SumWithOrder(p []byte, order binary.ByteOrder) [4]uint64 {
+	var sum [4]uint64
+	for i := 0; i+4 <= len(p); i += 4 {
+		sum[0] += uint64(order.Uint32(p[i : i+4]))
+		sum[1] += sum[0]
+		sum[2] += sum[1]
+		sum[3] += sum[2]
+	}
+	return sum
+}
+
+// Combine folds two adjacently-positioned, independently computed fletcher4
+// quad sums into the quad sum of their concatenation. rightWords is the
+// number of 32-bit words that went into right. The arithmetic mirrors
+// fletcher4's own recurrence and wraps modulo 2^64 exactly as the scalar
+// path does.
+func Combine(left, right [4]uint64, rightWords uint64) [4]uint64 {
+	n := rightWords
+	tri := triangular(n)
+	tet := tetrahedral(n)
+
+	a := left[0] + right[0]
+	b := left[1] + right[1] + n*left[0]
+	c := left[2] + right[2] + n*left[1] + tri*left[0]
+	d := left[3] + right[3] + n*left[2] + tri*left[1] + tet*left[0]
+	return [4]uint64{a, b, c, d}
+}
+
+// triangular returns n*(n+1)/2 mod 2^64. n and n+1 are multiplied only
+// after dividing the even one of the pair by 2, so the result matches the
+// exact integer reduced mod 2^64 even when n*(n+1) itself would overflow
+// uint64 before the division (n above roughly 2^32 words).
+func triangular(n uint64) uint64 {
+	if n%2 == 0 {
+		return (n / 2) * (n + 1)
+	}
+	return n * ((n + 1) / 2)
+}
+
+// tetrahedral returns n*(n+1)*(n+2)/6 mod 2^64. Of any three consecutive
+// integers, exactly one is divisible by 3 and at least one is divisible by
+// 2; dividing those out before multiplying keeps the computation exact mod
+// 2^64 for every n, instead of truncating an already-overflowed product
+// (n above roughly 2.6M words, i.e. ~10MB, the product n*(n+1)*(n+2)
+// overflows uint64 well before reaching the /6).
+func tetrahedral(n uint64) uint64 {
+	a, b, c := n, n+1, n+2
+	switch {
+	case a%2 == 0:
+		a /= 2
+	case b%2 == 0:
+		b /= 2
+	default:
+		c /= 2
+	}
+	switch {
+	case a%3 == 0:
+		a /= 3
+	case b%3 == 0:
+		b /= 3
+	default:
+		c /= 3
+	}
+	return a * b * c
+}
+
+// simdSum runs an interleaved-lane SIMD accumulator over the
+// lanes*BlockSize-aligned prefix of p, reduces the lane partials into a
+// single quad, then folds in the (at most lanes-1 word) remainder via the
+// scalar path and Combine.
+func simdSum(lanes int, accumulate func(p []byte, out []uint64), p []byte) [4]uint64 {
+	group := lanes * 4
+	simdLen := len(p) - len(p)%group
+
+	var result [4]uint64
+	if simdLen > 0 {
+		out := make([]uint64, 4*lanes)
+		accumulate(p[:simdLen], out)
+		result = reduceLanes(out, lanes)
+	}
+	if simdLen < len(p) {
+		tail := ScalarSum(p[simdLen:])
+		result = Combine(result, tail, uint64((len(p)-simdLen)/4))
+	}
+	return result
+}
+
+// Scalar is the portable, non-SIMD kernel. It backs NewScalar and is the
+// dispatch fallback on architectures or builds with no accelerated kernel.
+var Scalar = Kernel{Name: "scalar", Sum: ScalarSum}
+
+// Best is the fastest kernel available on this machine, chosen at init()
+// time. It defaults to Scalar and is overridden by the arch-specific
+// dispatch files (build-tagged out under -tags purego).
+var Best = Scalar
@@ -0,0 +1,24 @@
+//go:build !purego
+
+package asm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestKernelsMatchScalar(t *testing.T) {
+	kernels := []Kernel{SSE2, AVX2, AVX512}
+	r := rand.New(rand.NewSource(1))
+	for _, k := range kernels {
+		for _, n := range []int{0, 4, 8, 12, 16, 32, 60, 64, 128, 1000, 4099 * 4} {
+			p := make([]byte, n)
+			r.Read(p)
+			want := ScalarSum(p)
+			got := k.Sum(p)
+			if got != want {
+				t.Fatalf("%s: len %d: got %v want %v", k.Name, n, got, want)
+			}
+		}
+	}
+}
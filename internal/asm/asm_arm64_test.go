@@ -0,0 +1,21 @@
+//go:build !purego
+
+package asm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNEONMatchesScalar(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 4, 8, 12, 16, 32, 60, 64, 128, 1000, 4099 * 4} {
+		p := make([]byte, n)
+		r.Read(p)
+		want := ScalarSum(p)
+		got := NEON.Sum(p)
+		if got != want {
+			t.Fatalf("neon: len %d: got %v want %v", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !purego
+
+package asm
+
+import "testing"
+
+func BenchmarkKernels(b *testing.B) {
+	kernels := []Kernel{Scalar, SSE2, AVX2, AVX512}
+	for _, sz := range benchSizes {
+		for _, k := range kernels {
+			b.Run(sz.name+"/"+k.Name, func(b *testing.B) {
+				benchmarkKernel(b, k, sz.n)
+			})
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !purego
+
+package asm
+
+import "testing"
+
+var benchSizes = []struct {
+	name string
+	n    int
+}{
+	{"4KiB", 4 * 1024},
+	{"128KiB", 128 * 1024},
+	{"1MiB", 1024 * 1024},
+}
+
+func benchmarkKernel(b *testing.B, k Kernel, size int) {
+	p := make([]byte, size)
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k.Sum(p)
+	}
+}
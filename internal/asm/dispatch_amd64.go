@@ -0,0 +1,54 @@
+//go:build amd64 && !purego
+
+package asm
+
+// cpuid is implemented in cpuid_amd64.s; it avoids a dependency on
+// golang.org/x/sys/cpu since this module otherwise has none.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// getXCR0 is implemented in cpuid_amd64.s; it reports which register sets
+// the OS has enabled for SIMD use (XGETBV), needed before trusting AVX/
+// AVX-512 CPUID feature bits.
+func getXCR0() (xcr0 uint64)
+
+var (
+	hasAVX2    bool
+	hasAVX512F bool
+)
+
+func init() {
+	_, _, c1, _ := cpuid(1, 0)
+	osxsave := c1&(1<<27) != 0
+	if !osxsave {
+		Best = SSE2
+		return
+	}
+	xcr0 := getXCR0()
+	avxEnabled := xcr0&0x6 == 0x6
+	avx512Enabled := xcr0&0xe6 == 0xe6
+
+	_, b7, _, _ := cpuid(7, 0)
+	hasAVX2 = avxEnabled && b7&(1<<5) != 0
+	hasAVX512F = avx512Enabled && b7&(1<<16) != 0
+
+	switch {
+	case hasAVX512F:
+		Best = AVX512
+	case hasAVX2:
+		Best = AVX2
+	default:
+		Best = SSE2
+	}
+}
+
+var SSE2 = Kernel{Name: "sse2", Sum: func(p []byte) [4]uint64 {
+	return simdSum(2, func(p []byte, out []uint64) { accumulateSSE2(p, (*[8]uint64)(out)) }, p)
+}}
+
+var AVX2 = Kernel{Name: "avx2", Sum: func(p []byte) [4]uint64 {
+	return simdSum(4, func(p []byte, out []uint64) { accumulateAVX2(p, (*[16]uint64)(out)) }, p)
+}}
+
+var AVX512 = Kernel{Name: "avx512", Sum: func(p []byte) [4]uint64 {
+	return simdSum(8, func(p []byte, out []uint64) { accumulateAVX512(p, (*[32]uint64)(out)) }, p)
+}}
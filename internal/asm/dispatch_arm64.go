@@ -0,0 +1,13 @@
+//go:build arm64 && !purego
+
+package asm
+
+// NEON is always available on arm64, so there is no runtime feature probe
+// here (unlike amd64, where SSE2/AVX2/AVX-512F availability varies).
+func init() {
+	Best = NEON
+}
+
+var NEON = Kernel{Name: "neon", Sum: func(p []byte) [4]uint64 {
+	return simdSum(2, func(p []byte, out []uint64) { accumulateNEON(p, (*[8]uint64)(out)) }, p)
+}}
@@ -0,0 +1,16 @@
+//go:build amd64 && !purego
+
+package asm
+
+// accumulateSSE2 processes 2-word-interleaved lanes using SSE2 instructions.
+// len(p) must be a multiple of 8 bytes (2 words). out receives the 8
+// resulting 64-bit lane values in the order A0,A1,B0,B1,C0,C1,D0,D1.
+//
+//go:noescape
+func accumulateSSE2(p []byte, out *[8]uint64)
+
+//go:noescape
+func accumulateAVX2(p []byte, out *[16]uint64)
+
+//go:noescape
+func accumulateAVX512(p []byte, out *[32]uint64)
@@ -0,0 +1,10 @@
+//go:build arm64 && !purego
+
+package asm
+
+// accumulateNEON processes 2-word-interleaved lanes using NEON instructions.
+// len(p) must be a multiple of 8 bytes (2 words). out receives the 8
+// resulting 64-bit lane values in the order A0,A1,B0,B1,C0,C1,D0,D1.
+//
+//go:noescape
+func accumulateNEON(p []byte, out *[8]uint64)
@@ -0,0 +1,39 @@
+package asm
+
+// reduceLanes folds the L independent per-lane partial sums produced by an
+// interleaved SIMD accumulator (lane i owns the words at stream positions
+// i, i+L, i+2L, ...) into the single [A,B,C,D] quad that a plain serial
+// fletcher4 pass over the same bytes would have produced.
+//
+// lanes holds the L A-values, then the L B-values, C-values and D-values,
+// each as if that lane's own strided word subsequence had been run through
+// the ordinary scalar recurrence in isolation. The weights below come from
+// expanding fletcher4's closed-form position weighting (the same identity
+// Combine uses) in terms of a word's lane index and round number.
+func reduceLanes(lanes []uint64, numLanes int) [4]uint64 {
+	a := lanes[0*numLanes : 1*numLanes]
+	b := lanes[1*numLanes : 2*numLanes]
+	c := lanes[2*numLanes : 3*numLanes]
+	d := lanes[3*numLanes : 4*numLanes]
+
+	L := int64(numLanes)
+	var A, B, C, D uint64
+	for i := 0; i < numLanes; i++ {
+		li := int64(i)
+
+		A += a[i]
+		B += uint64(L)*b[i] - uint64(li)*a[i]
+
+		wC := L * L
+		wB := (L*(1-L))/2 - L*li
+		wA := (li * (li - 1)) / 2
+		C += uint64(wC)*c[i] + uint64(wB)*b[i] + uint64(wA)*a[i]
+
+		wD := L * L * L
+		wC2 := L * L * (1 - L - li)
+		wB2 := (L * (L*L - 3*L + 3*L*li + 3*li*li - 6*li + 2)) / 6
+		wA2 := -(li * (li - 1) * (li - 2)) / 6
+		D += uint64(wD)*d[i] + uint64(wC2)*c[i] + uint64(wB2)*b[i] + uint64(wA2)*a[i]
+	}
+	return [4]uint64{A, B, C, D}
+}
@@ -0,0 +1,99 @@
+// Copyright: Jostein Stuhaug
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fletcher4
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"go.solidsystem.no/fletcher4/internal/asm"
+)
+
+// Combine folds two adjacently-positioned, independently computed fletcher4
+// quad sums into the quad sum of their concatenation: left must be the sum
+// of some prefix of a stream, right the sum of the bytes that immediately
+// follow it, and rightWords the number of 32-bit words right was computed
+// over. This is the same closed-form merge digest.update already uses
+// internally to fold each Write into the running sum.
+func Combine(left, right [4]uint64, rightWords uint64) [4]uint64 {
+	return asm.Combine(left, right, rightWords)
+}
+
+// SumParallel computes the fletcher4 quad sum of p by sharding it into up
+// to workers pieces on BlockSize-aligned boundaries, checksumming each
+// shard concurrently, and folding the results together with Combine in
+// stream order. A trailing (<BlockSize byte) remainder is zero-padded and
+// folded in last, exactly as Sum64x4 pads an unfinished final word.
+//
+// workers below 1 is treated as 1; SumParallel never spawns more workers
+// than there are whole words to shard. Each shard is checksummed with
+// asm.Best, so like New's default, SumParallel decodes words little-endian;
+// it has no byte-order option.
+func SumParallel(p []byte, workers int) [4]uint64 {
+	if workers < 1 {
+		workers = 1
+	}
+
+	wordCount := len(p) / BlockSize
+	aligned := p[:wordCount*BlockSize]
+	tail := p[wordCount*BlockSize:]
+
+	if workers > wordCount {
+		workers = wordCount
+	}
+
+	type shard struct {
+		sum   [4]uint64
+		words uint64
+	}
+	shards := make([]shard, workers)
+	if workers > 0 {
+		base := wordCount / workers
+		extra := wordCount % workers
+
+		var wg sync.WaitGroup
+		offset := 0
+		for i := 0; i < workers; i++ {
+			words := base
+			if i < extra {
+				words++
+			}
+			chunk := aligned[offset : offset+words*BlockSize]
+			offset += words * BlockSize
+			shards[i].words = uint64(words)
+
+			wg.Add(1)
+			go func(i int, chunk []byte) {
+				defer wg.Done()
+				shards[i].sum = asm.Best.Sum(chunk)
+			}(i, chunk)
+		}
+		wg.Wait()
+	}
+
+	var total [4]uint64
+	for _, s := range shards {
+		total = Combine(total, s.sum, s.words)
+	}
+
+	if len(tail) > 0 {
+		var buf [BlockSize]byte
+		copy(buf[:], tail)
+		val := uint64(binary.LittleEndian.Uint32(buf[:]))
+		total = Combine(total, [4]uint64{val, val, val, val}, 1)
+	}
+
+	return total
+}
@@ -0,0 +1,152 @@
+// Copyright: Jostein Stuhaug
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fletcher4
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func serialSum(p []byte) [4]uint64 {
+	h := New()
+	h.Write(p)
+	return h.Sum64x4()
+}
+
+// Fuzz random buffer lengths and worker counts (including odd split
+// points that don't divide evenly) against the serial result.
+func TestSumParallelMatchesSerial(t *testing.T) {
+	r := rand.New(rand.NewSource(99))
+	for trial := 0; trial < 200; trial++ {
+		n := r.Intn(8192)
+		p := make([]byte, n)
+		r.Read(p)
+		workers := r.Intn(17) // 0..16, including the "treated as 1" case
+
+		want := serialSum(p)
+		got := SumParallel(p, workers)
+		if got != want {
+			t.Fatalf("trial %d: len %d, workers %d: got %v, want %v", trial, n, workers, got, want)
+		}
+	}
+}
+
+// Combine only folds whole words, so fuzz it directly against word-aligned
+// splits of a word-aligned buffer.
+func TestCombineMatchesSerial(t *testing.T) {
+	r := rand.New(rand.NewSource(100))
+	for trial := 0; trial < 200; trial++ {
+		words := r.Intn(1024)
+		p := make([]byte, words*BlockSize)
+		r.Read(p)
+		splitWords := r.Intn(words + 1)
+		split := splitWords * BlockSize
+
+		left := serialSum(p[:split])
+		right := serialSum(p[split:])
+		got := Combine(left, right, uint64(words-splitWords))
+
+		want := serialSum(p)
+		if got != want {
+			t.Fatalf("trial %d: words %d, splitWords %d: got %v, want %v", trial, words, splitWords, got, want)
+		}
+	}
+}
+
+// combineReference computes Combine's formula with big.Int, reducing mod
+// 2^64 only at the very end, so it can't hit the same truncating-division
+// overflow a naive uint64 implementation can. It's the reference
+// TestCombineLargeWordCounts checks against, since building actual
+// multi-GB buffers per trial would be far too slow.
+func combineReference(left, right [4]uint64, rightWords uint64) [4]uint64 {
+	mod := new(big.Int).Lsh(big.NewInt(1), 64)
+	toBig := func(v uint64) *big.Int { return new(big.Int).SetUint64(v) }
+
+	n := toBig(rightWords)
+	one := big.NewInt(1)
+	two := big.NewInt(2)
+	six := big.NewInt(6)
+	tri := new(big.Int).Mul(n, new(big.Int).Add(n, one))
+	tri.Div(tri, two)
+	tet := new(big.Int).Mul(n, new(big.Int).Add(n, one))
+	tet.Mul(tet, new(big.Int).Add(n, two))
+	tet.Div(tet, six)
+
+	l := [4]*big.Int{toBig(left[0]), toBig(left[1]), toBig(left[2]), toBig(left[3])}
+	rgt := [4]*big.Int{toBig(right[0]), toBig(right[1]), toBig(right[2]), toBig(right[3])}
+
+	a := new(big.Int).Add(l[0], rgt[0])
+	b := new(big.Int).Add(l[1], rgt[1])
+	b.Add(b, new(big.Int).Mul(n, l[0]))
+	c := new(big.Int).Add(l[2], rgt[2])
+	c.Add(c, new(big.Int).Mul(n, l[1]))
+	c.Add(c, new(big.Int).Mul(tri, l[0]))
+	d := new(big.Int).Add(l[3], rgt[3])
+	d.Add(d, new(big.Int).Mul(n, l[2]))
+	d.Add(d, new(big.Int).Mul(tri, l[1]))
+	d.Add(d, new(big.Int).Mul(tet, l[0]))
+
+	var out [4]uint64
+	for i, v := range [4]*big.Int{a, b, c, d} {
+		v.Mod(v, mod)
+		out[i] = v.Uint64()
+	}
+	return out
+}
+
+// Regression test for an overflow in Combine's triangular/tetrahedral terms:
+// n*(n+1)*(n+2) overflows uint64 before the /6 once rightWords exceeds
+// ~2.6M words, and truncating integer division doesn't commute with the
+// mod-2^64 wraparound that overflow causes, so the old code silently
+// returned a wrong D word past that threshold. SumParallel and multi-Write
+// streaming both reach this via Combine, well within "multi-GB" inputs.
+func TestCombineLargeWordCounts(t *testing.T) {
+	r := rand.New(rand.NewSource(101))
+	wordCounts := []uint64{
+		2_600_001,
+		3_000_000,
+		1 << 32,
+		(1 << 33) + 7,
+	}
+	for _, n := range wordCounts {
+		left := [4]uint64{r.Uint64(), r.Uint64(), r.Uint64(), r.Uint64()}
+		right := [4]uint64{r.Uint64(), r.Uint64(), r.Uint64(), r.Uint64()}
+
+		got := Combine(left, right, n)
+		want := combineReference(left, right, n)
+		if got != want {
+			t.Fatalf("rightWords %d: got %v, want %v", n, got, want)
+		}
+	}
+}
+
+func BenchmarkSumParallel(b *testing.B) {
+	sizes := []int{1 << 20, 16 << 20}
+	maxWorkers := runtime.GOMAXPROCS(0)
+	for _, n := range sizes {
+		p := make([]byte, n)
+		for w := 1; w <= maxWorkers; w *= 2 {
+			b.Run(fmt.Sprintf("size=%d/workers=%d", n, w), func(b *testing.B) {
+				b.SetBytes(int64(n))
+				for i := 0; i < b.N; i++ {
+					SumParallel(p, w)
+				}
+			})
+		}
+	}
+}